@@ -0,0 +1,113 @@
+package zapdriver
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var httpPayloadPool = sync.Pool{
+	New: func() interface{} { return &HTTPPayload{} },
+}
+
+// HTTPBuilder incrementally fills a pooled HTTPPayload and writes it to a
+// zap.CheckedEntry, without allocating or running MarshalLogObject when the
+// target log level is disabled.
+//
+// Obtain one from CheckedHTTP; a nil *HTTPBuilder is valid and every method
+// on it is a no-op, so callers can chain without checking for disabled
+// levels themselves.
+type HTTPBuilder struct {
+	ce      *zapcore.CheckedEntry
+	payload *HTTPPayload
+}
+
+// CheckedHTTP checks whether lvl is enabled on logger and, if so, returns an
+// HTTPBuilder backed by a pooled HTTPPayload. If the level is disabled, it
+// returns nil and the caller can skip building the payload entirely.
+func CheckedHTTP(logger *zap.Logger, lvl zapcore.Level, msg string) *HTTPBuilder {
+	ce := logger.Check(lvl, msg)
+	if ce == nil {
+		return nil
+	}
+
+	return &HTTPBuilder{ce: ce, payload: httpPayloadPool.Get().(*HTTPPayload)}
+}
+
+// Method sets the request method.
+func (b *HTTPBuilder) Method(method string) *HTTPBuilder {
+	if b == nil {
+		return nil
+	}
+	b.payload.RequestMethod = method
+	return b
+}
+
+// URL sets the request URL.
+func (b *HTTPBuilder) URL(url string) *HTTPBuilder {
+	if b == nil {
+		return nil
+	}
+	b.payload.RequestURL = url
+	return b
+}
+
+// Status sets the response status code.
+func (b *HTTPBuilder) Status(status int) *HTTPBuilder {
+	if b == nil {
+		return nil
+	}
+	b.payload.Status = status
+	return b
+}
+
+// Latency sets the request latency.
+func (b *HTTPBuilder) Latency(d time.Duration) *HTTPBuilder {
+	if b == nil {
+		return nil
+	}
+	b.payload.SetLatency(d)
+	return b
+}
+
+// Bytes sets the request and response sizes, in bytes.
+func (b *HTTPBuilder) Bytes(reqN, resN int64) *HTTPBuilder {
+	if b == nil {
+		return nil
+	}
+	b.payload.RequestSize = formatByteCount(reqN)
+	b.payload.ResponseSize = formatByteCount(resN)
+	return b
+}
+
+// Write emits the log entry with the accumulated HTTPPayload plus any extra
+// fields, and returns the payload to the pool. The builder must not be used
+// again after calling Write.
+//
+// zap.Object only stores a pointer to its argument; some cores (notably
+// zaptest/observer, used in tests) don't marshal it until well after Write
+// returns. So the pooled payload is copied before it goes back in the pool —
+// the log entry keeps its own copy, and the pool slot is free for reuse
+// immediately.
+func (b *HTTPBuilder) Write(extra ...zap.Field) {
+	if b == nil {
+		return
+	}
+
+	payload := *b.payload
+	fields := append(extra, HTTP(&payload))
+	b.ce.Write(fields...)
+
+	*b.payload = HTTPPayload{}
+	httpPayloadPool.Put(b.payload)
+}
+
+func formatByteCount(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
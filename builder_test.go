@@ -0,0 +1,89 @@
+package zapdriver
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCheckedHTTP(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	CheckedHTTP(logger, zapcore.InfoLevel, "request").
+		Method("GET").
+		URL("/one").
+		Status(200).
+		Latency(100*time.Millisecond).
+		Bytes(10, 20).
+		Write()
+
+	CheckedHTTP(logger, zapcore.InfoLevel, "request").
+		Method("POST").
+		URL("/two").
+		Status(500).
+		Latency(200*time.Millisecond).
+		Bytes(30, 40).
+		Write()
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+
+	want := []struct {
+		method string
+		url    string
+		status int
+	}{
+		{"GET", "/one", 200},
+		{"POST", "/two", 500},
+	}
+
+	for i, w := range want {
+		fields := entries[i].ContextMap()
+		req, ok := fields["httpRequest"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("entry %d: httpRequest field missing or wrong type: %#v", i, fields["httpRequest"])
+		}
+
+		if got := req["requestMethod"]; got != w.method {
+			t.Errorf("entry %d: requestMethod = %v, want %v", i, got, w.method)
+		}
+		if got := req["requestUrl"]; got != w.url {
+			t.Errorf("entry %d: requestUrl = %v, want %v", i, got, w.url)
+		}
+		switch got := req["status"].(type) {
+		case int64:
+			if int(got) != w.status {
+				t.Errorf("entry %d: status = %v, want %v", i, got, w.status)
+			}
+		case int:
+			if got != w.status {
+				t.Errorf("entry %d: status = %v, want %v", i, got, w.status)
+			}
+		default:
+			t.Errorf("entry %d: status has unexpected type %T: %v", i, req["status"], req["status"])
+		}
+	}
+}
+
+func TestCheckedHTTPDisabledLevel(t *testing.T) {
+	core, logs := observer.New(zapcore.ErrorLevel)
+	logger := zap.New(core)
+
+	b := CheckedHTTP(logger, zapcore.InfoLevel, "request")
+	if b != nil {
+		t.Fatalf("expected nil builder for disabled level, got %#v", b)
+	}
+
+	// Chaining and Write on a nil builder must be safe no-ops.
+	b.Method("GET").Status(200).Write()
+
+	if len(logs.All()) != 0 {
+		t.Fatalf("expected no log entries, got %d", len(logs.All()))
+	}
+}
@@ -0,0 +1,51 @@
+package zapdriver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{time.Second, "1s"},
+		{3500 * time.Millisecond, "3.5s"},
+		{time.Nanosecond, "0.000000001s"},
+		{2*time.Second + 150*time.Millisecond, "2.15s"},
+		{-3500 * time.Millisecond, "-3.5s"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPPayloadSetLatency(t *testing.T) {
+	payload := &HTTPPayload{}
+	payload.SetLatency(2500 * time.Millisecond)
+
+	if payload.Latency != "2.5s" {
+		t.Errorf("Latency = %q, want %q", payload.Latency, "2.5s")
+	}
+}
+
+func TestNewHTTPWithTimes(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	res := &http.Response{StatusCode: 200}
+
+	start := time.Unix(0, 0)
+	end := start.Add(1500 * time.Millisecond)
+
+	payload := NewHTTPWithTimes(req, res, start, end)
+
+	if payload.Latency != "1.5s" {
+		t.Errorf("Latency = %q, want %q", payload.Latency, "1.5s")
+	}
+}
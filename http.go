@@ -15,6 +15,8 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -101,6 +103,13 @@ type HTTPPayload struct {
 
 // NewHTTP returns a new HTTPPayload struct, based on the passed
 // in http.Request and http.Response objects.
+//
+// It never reads from req.Body or res.Body, which would otherwise drain
+// them for any downstream handler or client. Sizes are taken from
+// Content-Length when the caller (or net/http) has already set it, and left
+// unset otherwise. Use WrapRequest/WrapResponse to measure a body as it is
+// naturally consumed elsewhere in the pipeline, or NewHTTPEager to force an
+// eager, destructive read when nothing else will consume the body.
 func NewHTTP(req *http.Request, res *http.Response) *HTTPPayload {
 	if req == nil {
 		req = &http.Request{}
@@ -123,14 +132,31 @@ func NewHTTP(req *http.Request, res *http.Response) *HTTPPayload {
 		sdreq.RequestURL = req.URL.String()
 	}
 
-	buf := &bytes.Buffer{}
-	if req.Body != nil {
+	if req.ContentLength > 0 {
+		sdreq.RequestSize = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	if res.ContentLength > 0 {
+		sdreq.ResponseSize = strconv.FormatInt(res.ContentLength, 10)
+	}
+
+	return sdreq
+}
+
+// NewHTTPEager is like NewHTTP, but eagerly drains req.Body and res.Body to
+// compute their exact sizes. Only use it when nothing downstream needs to
+// read those bodies, since both are consumed irrecoverably in the process.
+func NewHTTPEager(req *http.Request, res *http.Response) *HTTPPayload {
+	sdreq := NewHTTP(req, res)
+
+	if req != nil && req.Body != nil {
+		buf := &bytes.Buffer{}
 		n, _ := io.Copy(buf, req.Body) // nolint: gas
 		sdreq.RequestSize = strconv.FormatInt(n, 10)
 	}
 
-	if res.Body != nil {
-		buf.Reset()
+	if res != nil && res.Body != nil {
+		buf := &bytes.Buffer{}
 		n, _ := io.Copy(buf, res.Body) // nolint: gas
 		sdreq.ResponseSize = strconv.FormatInt(n, 10)
 	}
@@ -138,6 +164,108 @@ func NewHTTP(req *http.Request, res *http.Response) *HTTPPayload {
 	return sdreq
 }
 
+// WrapRequest wraps req.Body in a counting io.ReadCloser and returns the
+// replacement body alongside an HTTPPayload whose RequestSize is updated
+// lazily, in place, as the body is read by its real consumer. Swap
+// req.Body for the returned body before handing the request off, and read
+// RequestSize off the returned payload only after the body has been fully
+// read (e.g. once the handler has returned).
+//
+// If req.Body is nil, the returned body is nil and the payload is left
+// exactly as NewHTTP would build it.
+func WrapRequest(req *http.Request) (io.ReadCloser, *HTTPPayload) {
+	payload := NewHTTP(req, nil)
+
+	if req == nil || req.Body == nil {
+		return nil, payload
+	}
+
+	body := &countingReadCloser{ReadCloser: req.Body, payload: payload, setSize: setRequestSize}
+	return body, payload
+}
+
+// WrapResponse wraps res.Body in a counting io.ReadCloser and returns the
+// replacement body alongside an HTTPPayload whose ResponseSize is updated
+// lazily, in place, as the body is read by its real consumer. Swap
+// res.Body for the returned body before handing the response off, and read
+// ResponseSize off the returned payload only after the body has been fully
+// read.
+//
+// If res.Body is nil, the returned body is nil and the payload is left
+// exactly as NewHTTP would build it.
+func WrapResponse(res *http.Response) (io.ReadCloser, *HTTPPayload) {
+	payload := NewHTTP(nil, res)
+
+	if res == nil || res.Body == nil {
+		return nil, payload
+	}
+
+	body := &countingReadCloser{ReadCloser: res.Body, payload: payload, setSize: setResponseSize}
+	return body, payload
+}
+
+func setRequestSize(p *HTTPPayload, n int64)  { p.RequestSize = strconv.FormatInt(n, 10) }
+func setResponseSize(p *HTTPPayload, n int64) { p.ResponseSize = strconv.FormatInt(n, 10) }
+
+// NewHTTPWithTimes is like NewHTTP, but also sets Latency from the given
+// start and end times, formatted the way Stackdriver expects.
+func NewHTTPWithTimes(req *http.Request, res *http.Response, start, end time.Time) *HTTPPayload {
+	sdreq := NewHTTP(req, res)
+	sdreq.SetLatency(end.Sub(start))
+	return sdreq
+}
+
+// SetLatency sets Latency from d, formatted by FormatDuration the way
+// Stackdriver's HttpRequest.latency expects (e.g. "2.5s").
+func (req *HTTPPayload) SetLatency(d time.Duration) {
+	req.Latency = FormatDuration(d)
+}
+
+// FormatDuration renders d the way Stackdriver's HttpRequest.latency (and
+// protobuf's google.protobuf.Duration) expect: integer seconds, followed by
+// a '.' and the fractional nanoseconds with trailing zeroes trimmed, then a
+// terminating 's'. A duration with no fractional part is rendered as just
+// "Ns".
+//
+// Example: 3500*time.Millisecond -> "3.5s".
+func FormatDuration(d time.Duration) string {
+	neg := ""
+	if d < 0 {
+		neg = "-"
+		d = -d
+	}
+
+	seconds := int64(d / time.Second)
+	nanos := int64(d % time.Second)
+
+	if nanos == 0 {
+		return neg + strconv.FormatInt(seconds, 10) + "s"
+	}
+
+	frac := strconv.FormatInt(nanos, 10)
+	frac = strings.Repeat("0", 9-len(frac)) + frac
+	frac = strings.TrimRight(frac, "0")
+
+	return neg + strconv.FormatInt(seconds, 10) + "." + frac + "s"
+}
+
+// countingReadCloser counts bytes as they are read through it by a body's
+// real consumer, writing the running total into an HTTPPayload so that size
+// tracking never requires an extra, destructive read of the body.
+type countingReadCloser struct {
+	io.ReadCloser
+	payload *HTTPPayload
+	setSize func(*HTTPPayload, int64)
+	n       int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	c.setSize(c.payload, c.n)
+	return n, err
+}
+
 // MarshalLogObject implements zapcore.ObjectMarshaller interface.
 func (req HTTPPayload) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	if req.RequestMethod != "" {
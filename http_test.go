@@ -0,0 +1,107 @@
+package zapdriver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewHTTPDoesNotDrainBodies(t *testing.T) {
+	reqBody := "request body"
+	resBody := "response body"
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	// Assign the body after NewRequest (which got a nil body), so
+	// req.ContentLength stays 0, exercising the no-Content-Length path.
+	req.Body = io.NopCloser(strings.NewReader(reqBody))
+	res := &http.Response{Body: io.NopCloser(strings.NewReader(resBody))}
+
+	payload := NewHTTP(req, res)
+
+	if payload.RequestSize != "" {
+		t.Errorf("RequestSize = %q, want empty (Content-Length unset)", payload.RequestSize)
+	}
+	if payload.ResponseSize != "" {
+		t.Errorf("ResponseSize = %q, want empty (Content-Length unset)", payload.ResponseSize)
+	}
+
+	gotReqBody, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("reading req.Body: %v", err)
+	}
+	if string(gotReqBody) != reqBody {
+		t.Errorf("req.Body was drained: got %q, want %q", gotReqBody, reqBody)
+	}
+
+	gotResBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("reading res.Body: %v", err)
+	}
+	if string(gotResBody) != resBody {
+		t.Errorf("res.Body was drained: got %q, want %q", gotResBody, resBody)
+	}
+}
+
+func TestNewHTTPUsesContentLength(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello"))
+	req.ContentLength = 5
+	res := &http.Response{ContentLength: 7}
+
+	payload := NewHTTP(req, res)
+
+	if payload.RequestSize != "5" {
+		t.Errorf("RequestSize = %q, want %q", payload.RequestSize, "5")
+	}
+	if payload.ResponseSize != "7" {
+		t.Errorf("ResponseSize = %q, want %q", payload.ResponseSize, "7")
+	}
+}
+
+func TestNewHTTPEagerDrainsBodies(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello"))
+	res := &http.Response{Body: io.NopCloser(strings.NewReader("response"))}
+
+	payload := NewHTTPEager(req, res)
+
+	if payload.RequestSize != "5" {
+		t.Errorf("RequestSize = %q, want %q", payload.RequestSize, "5")
+	}
+	if payload.ResponseSize != "8" {
+		t.Errorf("ResponseSize = %q, want %q", payload.ResponseSize, "8")
+	}
+}
+
+func TestWrapRequestUpdatesSizeLazily(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Body = io.NopCloser(strings.NewReader("hello world"))
+
+	body, payload := WrapRequest(req)
+	if payload.RequestSize != "" {
+		t.Errorf("RequestSize = %q before the body is read, want empty", payload.RequestSize)
+	}
+
+	got, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("reading wrapped body: %v", err)
+	}
+	if string(got) != "hello world" {
+		t.Errorf("wrapped body = %q, want %q", got, "hello world")
+	}
+
+	if payload.RequestSize != "11" {
+		t.Errorf("RequestSize = %q after reading the body, want %q", payload.RequestSize, "11")
+	}
+}
+
+func TestWrapResponseNilBody(t *testing.T) {
+	body, payload := WrapResponse(&http.Response{StatusCode: 204})
+
+	if body != nil {
+		t.Errorf("expected nil body, got %v", body)
+	}
+	if payload.Status != 204 {
+		t.Errorf("Status = %d, want 204", payload.Status)
+	}
+}
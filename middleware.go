@@ -0,0 +1,252 @@
+package zapdriver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// MiddlewareOption configures the behavior of HTTPMiddleware.
+type MiddlewareOption func(*middlewareConfig)
+
+// FieldsFromContext extracts additional zap.Fields from a request's context,
+// which get appended to the access log entry emitted by HTTPMiddleware.
+type FieldsFromContext func(ctx context.Context) []zap.Field
+
+type middlewareConfig struct {
+	skip          func(r *http.Request) bool
+	fieldsFromCtx FieldsFromContext
+	sniffBody     int
+	message       string
+	traceProject  string
+}
+
+// WithSkip configures a predicate that, when it returns true for a given
+// request, causes HTTPMiddleware to skip logging it entirely.
+//
+// Example: skip health checks.
+//
+//	zapdriver.WithSkip(func(r *http.Request) bool {
+//		return r.URL.Path == "/healthz"
+//	})
+func WithSkip(skip func(r *http.Request) bool) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.skip = skip
+	}
+}
+
+// WithFieldsFromContext attaches extra zap.Fields, extracted from the
+// request's context, to every access log entry.
+func WithFieldsFromContext(f FieldsFromContext) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.fieldsFromCtx = f
+	}
+}
+
+// WithBodySniff enables sniffing up to n bytes of the request body so it can
+// be inspected by a FieldsFromContext hook via SniffedBody, without
+// buffering the full body in memory. A value of 0 (the default) disables
+// sniffing.
+func WithBodySniff(n int) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.sniffBody = n
+	}
+}
+
+// WithMessage overrides the log message emitted for each request. Defaults
+// to "request".
+func WithMessage(msg string) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.message = msg
+	}
+}
+
+// WithTraceContext joins every access log entry to its Cloud Trace span, by
+// parsing the request's trace headers (see TraceContextFromRequest) and
+// attaching the resulting fields under the given Cloud project ID.
+func WithTraceContext(projectID string) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.traceProject = projectID
+	}
+}
+
+// HTTPMiddleware returns a net/http middleware that logs a single Stackdriver
+// HTTP access log entry per request, using the `httpRequest` field built
+// from the wrapped request and response.
+//
+// see: https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#HttpRequest
+func HTTPMiddleware(logger *zap.Logger, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	cfg := &middlewareConfig{message: "request"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.skip != nil && cfg.skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+
+			var body *sniffingReadCloser
+			if r.Body != nil {
+				body = newSniffingReadCloser(r.Body, cfg.sniffBody)
+				r.Body = body
+				r = r.WithContext(context.WithValue(r.Context(), sniffedBodyKey{}, body))
+			}
+
+			rw := wrapResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			payload := &HTTPPayload{
+				RequestMethod: r.Method,
+				Status:        rw.status,
+				UserAgent:     r.UserAgent(),
+				RemoteIP:      r.RemoteAddr,
+				Referer:       r.Referer(),
+				Protocol:      r.Proto,
+				ResponseSize:  strconv.FormatInt(rw.bytes, 10),
+			}
+			if r.URL != nil {
+				payload.RequestURL = r.URL.String()
+			}
+			switch {
+			case r.ContentLength > 0:
+				payload.RequestSize = strconv.FormatInt(r.ContentLength, 10)
+			case body != nil:
+				payload.RequestSize = strconv.FormatInt(body.n, 10)
+			}
+			payload.SetLatency(time.Since(start))
+
+			fields := []zap.Field{HTTP(payload)}
+			if cfg.traceProject != "" {
+				fields = append(fields, TraceContextFromRequest(r, cfg.traceProject)...)
+			}
+			if cfg.fieldsFromCtx != nil {
+				fields = append(fields, cfg.fieldsFromCtx(r.Context())...)
+			}
+
+			logger.Check(severityForStatus(rw.status), cfg.message).Write(fields...)
+		})
+	}
+}
+
+// severityForStatus maps an HTTP status class to a log level, following the
+// convention that 2xx/3xx are informational, 4xx are warnings, and 5xx are
+// errors.
+func severityForStatus(status int) zapcore.Level {
+	switch {
+	case status >= 500:
+		return zapcore.ErrorLevel
+	case status >= 400:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
+// responseWriter wraps an http.ResponseWriter to record the status code and
+// number of bytes written, so they can be reported without the handler
+// cooperating.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	rw.status = status
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Flush forwards to the underlying http.Flusher, if any, so streaming
+// handlers (e.g. SSE) keep working when wrapped by this middleware.
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack forwards to the underlying http.Hijacker, if any, so WebSocket
+// upgrades keep working when wrapped by this middleware.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("zapdriver: underlying ResponseWriter does not implement http.Hijacker")
+	}
+	return h.Hijack()
+}
+
+// Push forwards to the underlying http.Pusher, if any.
+func (rw *responseWriter) Push(target string, opts *http.PushOptions) error {
+	p, ok := rw.ResponseWriter.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return p.Push(target, opts)
+}
+
+// sniffedBodyKey is the context key under which the active
+// sniffingReadCloser for a request is stored.
+type sniffedBodyKey struct{}
+
+// SniffedBody returns up to the configured WithBodySniff limit of bytes read
+// from the request body so far. It is meant to be called from a
+// FieldsFromContext hook, after the downstream handler has read the body.
+func SniffedBody(ctx context.Context) []byte {
+	body, _ := ctx.Value(sniffedBodyKey{}).(*sniffingReadCloser)
+	if body == nil {
+		return nil
+	}
+	return body.buf.Bytes()
+}
+
+// sniffingReadCloser wraps an io.ReadCloser, counting every byte read
+// through it while copying up to limit bytes into an internal buffer for
+// later inspection.
+type sniffingReadCloser struct {
+	io.ReadCloser
+	limit int
+	n     int64
+	buf   bytes.Buffer
+}
+
+func newSniffingReadCloser(rc io.ReadCloser, limit int) *sniffingReadCloser {
+	return &sniffingReadCloser{ReadCloser: rc, limit: limit}
+}
+
+func (s *sniffingReadCloser) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	s.n += int64(n)
+
+	if remain := s.limit - s.buf.Len(); remain > 0 && n > 0 {
+		if remain > n {
+			remain = n
+		}
+		s.buf.Write(p[:remain])
+	}
+
+	return n, err
+}
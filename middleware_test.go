@@ -0,0 +1,98 @@
+package zapdriver
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHTTPMiddlewareRequestSizeFromContentLength(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// A handler that rejects the request without reading the body, as an
+	// auth check or method guard would.
+	handler := HTTPMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", 30)))
+	req.ContentLength = 30
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 log entry, got %d", len(entries))
+	}
+
+	req2 := entries[0].ContextMap()["httpRequest"].(map[string]interface{})
+	if got, want := req2["requestSize"], "30"; got != want {
+		t.Errorf("requestSize = %v, want %v (from Content-Length, not bytes actually read)", got, want)
+	}
+}
+
+type flushHijackResponseWriter struct {
+	*httptest.ResponseRecorder
+	flushed  bool
+	hijacked bool
+}
+
+func (w *flushHijackResponseWriter) Flush() { w.flushed = true }
+
+func (w *flushHijackResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+
+func TestHTTPMiddlewarePassesThroughFlusherAndHijacker(t *testing.T) {
+	logger := zap.NewNop()
+	underlying := &flushHijackResponseWriter{ResponseRecorder: httptest.NewRecorder()}
+
+	handler := HTTPMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("wrapped ResponseWriter does not implement http.Flusher")
+		}
+		f.Flush()
+
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("wrapped ResponseWriter does not implement http.Hijacker")
+		}
+		if _, _, err := h.Hijack(); err != nil {
+			t.Fatalf("Hijack() error = %v", err)
+		}
+	}))
+
+	handler.ServeHTTP(underlying, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !underlying.flushed {
+		t.Error("Flush() was not forwarded to the underlying ResponseWriter")
+	}
+	if !underlying.hijacked {
+		t.Error("Hijack() was not forwarded to the underlying ResponseWriter")
+	}
+}
+
+func TestHTTPMiddlewareHijackUnsupported(t *testing.T) {
+	logger := zap.NewNop()
+
+	handler := HTTPMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("wrapped ResponseWriter does not implement http.Hijacker")
+		}
+		if _, _, err := h.Hijack(); err == nil {
+			t.Fatal("expected Hijack() to error when underlying ResponseWriter does not support it")
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,127 @@
+package zapdriver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Stackdriver LogEntry fields used to correlate a log entry with a Cloud
+// Trace span. These live at the top level of the entry, not inside
+// `httpRequest`.
+//
+// see: https://cloud.google.com/logging/docs/structured-logging#structured_logging_special_fields
+const (
+	traceKey        = "logging.googleapis.com/trace"
+	spanKey         = "logging.googleapis.com/spanId"
+	traceSampledKey = "logging.googleapis.com/trace_sampled"
+)
+
+// TraceContext returns the `logging.googleapis.com/trace`,
+// `logging.googleapis.com/spanId` and `logging.googleapis.com/trace_sampled`
+// fields Stackdriver uses to join a log entry with its Cloud Trace span.
+//
+// traceID is formatted as "projects/{projectID}/traces/{traceID}", as
+// required by Cloud Logging.
+func TraceContext(traceID, spanID string, sampled bool, projectID string) []zap.Field {
+	fields := make([]zap.Field, 0, 3)
+
+	if traceID != "" {
+		fields = append(fields, zap.String(traceKey, fmt.Sprintf("projects/%s/traces/%s", projectID, traceID)))
+	}
+
+	if spanID != "" {
+		fields = append(fields, zap.String(spanKey, spanID))
+	}
+
+	fields = append(fields, zap.Bool(traceSampledKey, sampled))
+
+	return fields
+}
+
+// TraceContextFromRequest extracts the trace/spanId/traceSampled fields from
+// an incoming request, reading either the Google `X-Cloud-Trace-Context`
+// header or, failing that, the W3C `traceparent` header. It returns nil if
+// neither header is present.
+func TraceContextFromRequest(r *http.Request, projectID string) []zap.Field {
+	if h := r.Header.Get("X-Cloud-Trace-Context"); h != "" {
+		if traceID, spanID, sampled, ok := parseCloudTraceContext(h); ok {
+			return TraceContext(traceID, spanID, sampled, projectID)
+		}
+	}
+
+	if h := r.Header.Get("traceparent"); h != "" {
+		if traceID, spanID, sampled, ok := parseTraceParent(h); ok {
+			return TraceContext(traceID, spanID, sampled, projectID)
+		}
+	}
+
+	return nil
+}
+
+// parseCloudTraceContext parses the `X-Cloud-Trace-Context` header, of the
+// form "TRACE_ID/SPAN_ID;o=TRACE_TRUE".
+//
+// SPAN_ID is carried as a decimal uint64 in this header, but Cloud Logging's
+// `logging.googleapis.com/spanId` field requires a 16-character hex-encoded
+// span ID (the same form the W3C traceparent path already produces), so it
+// is converted here.
+//
+// see: https://cloud.google.com/trace/docs/setup#force-trace
+func parseCloudTraceContext(h string) (traceID, spanID string, sampled bool, ok bool) {
+	slash := strings.IndexByte(h, '/')
+	if slash < 0 {
+		return "", "", false, false
+	}
+
+	traceID = h[:slash]
+	rest := h[slash+1:]
+
+	rawSpanID := rest
+	if semi := strings.IndexByte(rest, ';'); semi >= 0 {
+		rawSpanID = rest[:semi]
+
+		if opts := rest[semi+1:]; strings.HasPrefix(opts, "o=") {
+			if v, err := strconv.Atoi(opts[2:]); err == nil {
+				sampled = v&1 == 1
+			}
+		}
+	}
+
+	if traceID == "" || rawSpanID == "" {
+		return "", "", false, false
+	}
+
+	spanIDUint, err := strconv.ParseUint(rawSpanID, 10, 64)
+	if err != nil {
+		return "", "", false, false
+	}
+
+	return traceID, fmt.Sprintf("%016x", spanIDUint), sampled, true
+}
+
+// parseTraceParent parses a W3C `traceparent` header, of the form
+// "version-traceId-spanId-flags".
+//
+// see: https://www.w3.org/TR/trace-context/#traceparent-header
+func parseTraceParent(h string) (traceID, spanID string, sampled bool, ok bool) {
+	parts := strings.Split(h, "-")
+	if len(parts) != 4 {
+		return "", "", false, false
+	}
+
+	traceID, spanID, flags := parts[1], parts[2], parts[3]
+	if traceID == "" || spanID == "" || len(flags) != 2 {
+		return "", "", false, false
+	}
+
+	flagBits, err := strconv.ParseUint(flags, 16, 8)
+	if err != nil {
+		return "", "", false, false
+	}
+
+	return traceID, spanID, flagBits&1 == 1, true
+}
@@ -0,0 +1,60 @@
+package zapdriver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func fieldsToMap(fields []zap.Field) map[string]interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return enc.Fields
+}
+
+func TestTraceContextFromRequestCloudTraceContext(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Cloud-Trace-Context", "105445aa7843bc8bf206b120001000/12345;o=1")
+
+	fields := fieldsToMap(TraceContextFromRequest(r, "my-project"))
+
+	if got, want := fields[traceKey], "projects/my-project/traces/105445aa7843bc8bf206b120001000"; got != want {
+		t.Errorf("trace = %q, want %q", got, want)
+	}
+	if got, want := fields[spanKey], "0000000000003039"; got != want {
+		t.Errorf("spanId = %q, want %q (hex-encoded, not decimal)", got, want)
+	}
+	if got, want := fields[traceSampledKey], true; got != want {
+		t.Errorf("trace_sampled = %v, want %v", got, want)
+	}
+}
+
+func TestTraceContextFromRequestTraceParent(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b3-01")
+
+	fields := fieldsToMap(TraceContextFromRequest(r, "my-project"))
+
+	if got, want := fields[traceKey], "projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736"; got != want {
+		t.Errorf("trace = %q, want %q", got, want)
+	}
+	if got, want := fields[spanKey], "00f067aa0ba902b3"; got != want {
+		t.Errorf("spanId = %q, want %q", got, want)
+	}
+	if got, want := fields[traceSampledKey], true; got != want {
+		t.Errorf("trace_sampled = %v, want %v", got, want)
+	}
+}
+
+func TestTraceContextFromRequestNoHeaders(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if fields := TraceContextFromRequest(r, "my-project"); fields != nil {
+		t.Errorf("expected nil fields, got %v", fields)
+	}
+}